@@ -0,0 +1,111 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// PodGroupPlural is the plural name used to register the PodGroup CRD.
+const PodGroupPlural = "podgroups"
+
+// GroupNameAnnotation is set on a pod to declare which PodGroup it is a
+// member of. The scheduler and schedulercache key off this annotation
+// rather than a label so it survives through admission unchanged.
+const GroupNameAnnotation = "scheduling.k8s.io/group-name"
+
+// PodGroup is a CRD that describes a gang of pods that must be
+// scheduled together: either at least MinMember of them run, or none
+// do.
+type PodGroup struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   PodGroupSpec   `json:"spec,omitempty"`
+	Status PodGroupStatus `json:"status,omitempty"`
+}
+
+// PodGroupSpec holds the desired state of a PodGroup.
+type PodGroupSpec struct {
+	// MinMember is the minimum number of pods in this group that must
+	// be runnable before any of them are admitted.
+	MinMember int32 `json:"minMember,omitempty"`
+
+	// Queue is the name of the Queue this group's pods are charged
+	// against.
+	Queue string `json:"queue,omitempty"`
+
+	// Priority is used to order PodGroups competing for the same
+	// queue's deserved allocation.
+	Priority int32 `json:"priority,omitempty"`
+
+	// MinResources is the total amount of resources MinMember pods of
+	// this group need, used by the policy as the group's demand in place
+	// of its member pods' individual requests.
+	MinResources ResourceList `json:"minResources,omitempty"`
+}
+
+// PodGroupStatus holds the observed state of a PodGroup.
+type PodGroupStatus struct {
+	// Running is the number of this group's pods currently running.
+	Running int32 `json:"running,omitempty"`
+}
+
+// PodGroupList is a list of PodGroup resources.
+type PodGroupList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []PodGroup `json:"items"`
+}
+
+// DeepCopy returns a deep copy of the PodGroupSpec.
+func (in PodGroupSpec) DeepCopy() PodGroupSpec {
+	out := in
+	out.MinResources = in.MinResources.DeepCopy()
+	return out
+}
+
+// DeepCopy returns a deep copy of the PodGroup.
+func (in *PodGroup) DeepCopy() *PodGroup {
+	if in == nil {
+		return nil
+	}
+	out := *in
+	out.ObjectMeta = *in.ObjectMeta.DeepCopy()
+	out.Spec = in.Spec.DeepCopy()
+	return &out
+}
+
+// DeepCopyObject implements runtime.Object.
+func (in *PodGroup) DeepCopyObject() runtime.Object {
+	return in.DeepCopy()
+}
+
+// DeepCopyObject implements runtime.Object.
+func (in *PodGroupList) DeepCopyObject() runtime.Object {
+	if in == nil {
+		return nil
+	}
+	out := &PodGroupList{TypeMeta: in.TypeMeta, ListMeta: in.ListMeta}
+	out.Items = make([]PodGroup, len(in.Items))
+	for i := range in.Items {
+		out.Items[i] = *in.Items[i].DeepCopy()
+	}
+	return out
+}
@@ -0,0 +1,171 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// QueuePlural is the plural name used to register the Queue CRD.
+const QueuePlural = "queues"
+
+// ResourceName is the name of a compute resource, e.g. "cpu", "memory".
+type ResourceName string
+
+// ResourceList is a set of (resource name, quantity) pairs.
+type ResourceList map[ResourceName]resource.Quantity
+
+// ResourceInfo wraps a ResourceList so it can be embedded in QueueStatus
+// under a named field (Allocated, Deserved, Used, ...).
+type ResourceInfo struct {
+	Resources ResourceList `json:"resources,omitempty"`
+}
+
+// PreemptionPolicy controls whether a queue's pods may be evicted to
+// satisfy another queue's deserved share.
+type PreemptionPolicy string
+
+const (
+	// PreemptLowerPriority allows the queue to be reclaimed from; victims
+	// are chosen by ascending pod priority.
+	PreemptLowerPriority PreemptionPolicy = "PreemptLowerPriority"
+	// Never marks the queue as non-preemptible: none of its pods will be
+	// evicted to satisfy another queue's deserved share.
+	Never PreemptionPolicy = "Never"
+)
+
+// Queue is a CRD that represents a tenant's share of the cluster.
+type Queue struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   QueueSpec   `json:"spec,omitempty"`
+	Status QueueStatus `json:"status,omitempty"`
+}
+
+// QueueSpec holds the desired state of a Queue.
+type QueueSpec struct {
+	// Weight decides how leftover cluster capacity (above every queue's
+	// Min) is split between queues that are still under their Max.
+	Weight int `json:"weight,omitempty"`
+
+	// Min is the guaranteed floor the queue is always entitled to,
+	// regardless of weight; it is never reclaimed by preemption.
+	Min ResourceList `json:"min,omitempty"`
+
+	// Max is the ceiling the queue may borrow up to when other queues
+	// are idle. A nil/empty Max means unbounded.
+	Max ResourceList `json:"max,omitempty"`
+
+	// PreemptionPolicy decides whether this queue's pods can be evicted
+	// to satisfy another queue's deserved share. Defaults to
+	// PreemptLowerPriority.
+	PreemptionPolicy PreemptionPolicy `json:"preemptionPolicy,omitempty"`
+}
+
+// QueueStatus holds the observed state of a Queue, recomputed by the
+// policy on every scheduling cycle.
+type QueueStatus struct {
+	// Guaranteed is Min clamped by actual demand; Used is never
+	// reclaimed below this line.
+	Guaranteed ResourceInfo `json:"guaranteed,omitempty"`
+	// Deserved is the fair share this queue is entitled to this cycle.
+	Deserved ResourceInfo `json:"deserved,omitempty"`
+	// Allocated is what the ResourceQuota writer has set as the queue's
+	// current hard limit.
+	Allocated ResourceInfo `json:"allocated,omitempty"`
+	// Used is the sum of requests of the queue's pods that have not
+	// terminated (pending or running).
+	Used ResourceInfo `json:"used,omitempty"`
+
+	Running int32 `json:"running,omitempty"`
+}
+
+// QueueList is a list of Queue resources.
+type QueueList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []Queue `json:"items"`
+}
+
+// DeepCopy returns a deep copy of the ResourceList.
+func (in ResourceList) DeepCopy() ResourceList {
+	if in == nil {
+		return nil
+	}
+	out := make(ResourceList, len(in))
+	for k, v := range in {
+		out[k] = v.DeepCopy()
+	}
+	return out
+}
+
+// DeepCopy returns a deep copy of the ResourceInfo.
+func (in ResourceInfo) DeepCopy() ResourceInfo {
+	return ResourceInfo{Resources: in.Resources.DeepCopy()}
+}
+
+// DeepCopy returns a deep copy of the QueueSpec.
+func (in QueueSpec) DeepCopy() QueueSpec {
+	out := in
+	out.Min = in.Min.DeepCopy()
+	out.Max = in.Max.DeepCopy()
+	return out
+}
+
+// DeepCopy returns a deep copy of the QueueStatus.
+func (in QueueStatus) DeepCopy() QueueStatus {
+	out := in
+	out.Guaranteed = in.Guaranteed.DeepCopy()
+	out.Deserved = in.Deserved.DeepCopy()
+	out.Allocated = in.Allocated.DeepCopy()
+	out.Used = in.Used.DeepCopy()
+	return out
+}
+
+// DeepCopy returns a deep copy of the Queue.
+func (in *Queue) DeepCopy() *Queue {
+	if in == nil {
+		return nil
+	}
+	out := *in
+	out.ObjectMeta = *in.ObjectMeta.DeepCopy()
+	out.Spec = in.Spec.DeepCopy()
+	out.Status = in.Status.DeepCopy()
+	return &out
+}
+
+// DeepCopyObject implements runtime.Object.
+func (in *Queue) DeepCopyObject() runtime.Object {
+	return in.DeepCopy()
+}
+
+// DeepCopyObject implements runtime.Object.
+func (in *QueueList) DeepCopyObject() runtime.Object {
+	if in == nil {
+		return nil
+	}
+	out := &QueueList{TypeMeta: in.TypeMeta, ListMeta: in.ListMeta}
+	out.Items = make([]Queue, len(in.Items))
+	for i := range in.Items {
+		out.Items[i] = *in.Items[i].DeepCopy()
+	}
+	return out
+}
@@ -0,0 +1,174 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package controller drives the arbitration loop: it asks the policy
+// for each queue's deserved share, asks the preemptor to reclaim
+// whatever is owed, and writes the result back as a ResourceQuota.
+package controller
+
+import (
+	"fmt"
+	"time"
+
+	apiv1 "github.com/kubernetes-incubator/kube-arbitrator/pkg/apis/v1"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/policy"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/policy/preemption"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/schedulercache"
+
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	restclient "k8s.io/client-go/rest"
+)
+
+// syncPeriod is how often the controller recomputes deserved shares and
+// reconciles ResourceQuotas.
+const syncPeriod = 2 * time.Second
+
+// QueueController reconciles Queue CRDs against namespace
+// ResourceQuotas, using a pluggable arbitration policy and preemptor.
+type QueueController struct {
+	config    *restclient.Config
+	client    kubernetes.Interface
+	cache     *schedulercache.Cache
+	policy    policy.Interface
+	preemptor preemption.Interface
+}
+
+// NewQueueController builds a controller that arbitrates queues found
+// in cache using the given policy and preemptor.
+func NewQueueController(config *restclient.Config, cache *schedulercache.Cache, p policy.Interface, pr preemption.Interface) *QueueController {
+	return &QueueController{
+		config:    config,
+		client:    kubernetes.NewForConfigOrDie(config),
+		cache:     cache,
+		policy:    p,
+		preemptor: pr,
+	}
+}
+
+// Run starts the reconciliation loop; it never returns.
+func (c *QueueController) Run() {
+	for {
+		if err := c.sync(); err != nil {
+			fmt.Printf("fail to sync queues, %#v\n", err)
+		}
+		time.Sleep(syncPeriod)
+	}
+}
+
+func (c *QueueController) sync() error {
+	queues := c.cache.Snapshot()
+	if len(queues) == 0 {
+		return nil
+	}
+
+	total, err := c.totalAllocatable()
+	if err != nil {
+		return err
+	}
+
+	if err := c.policy.Allocate(queues, total); err != nil {
+		return err
+	}
+
+	if err := c.preemptor.Preempt(queues); err != nil {
+		return err
+	}
+
+	for _, q := range queues {
+		if err := c.evictPartialGroups(q); err != nil {
+			return err
+		}
+		if err := c.writeResourceQuota(q); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// evictPartialGroups deletes every observed member pod of any
+// PodGroup that cannot reach MinMember within the queue's deserved
+// allocation, so a gang never sits stuck half-scheduled: it is either
+// fully up, or none of it runs.
+func (c *QueueController) evictPartialGroups(q *schedulercache.QueueInfo) error {
+	if q.AllGroupsReady() {
+		return nil
+	}
+
+	for _, g := range q.PodGroups {
+		if g.Ready() {
+			continue
+		}
+		for _, pod := range g.Pods {
+			if err := c.client.CoreV1().Pods(pod.Namespace).Delete(pod.Name, &metav1.DeleteOptions{}); err != nil {
+				return fmt.Errorf("fail to evict partial group member %s/%s, %#v", pod.Namespace, pod.Name, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (c *QueueController) totalAllocatable() (apiv1.ResourceList, error) {
+	nodes, err := c.client.CoreV1().Nodes().List(metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	total := apiv1.ResourceList{}
+	for _, node := range nodes.Items {
+		for name, qty := range node.Status.Capacity {
+			resName := apiv1.ResourceName(name)
+			t := total[resName]
+			t.Add(qty)
+			total[resName] = t
+		}
+	}
+	return total, nil
+}
+
+// writeResourceQuota pushes the queue's Deserved share down as the
+// namespace's ResourceQuota hard limits. It refuses to grow the quota
+// while one of the queue's PodGroups is stuck partially runnable,
+// since admitting only some of a group's pods would strand them.
+func (c *QueueController) writeResourceQuota(q *schedulercache.QueueInfo) error {
+	if !q.AllGroupsReady() {
+		return nil
+	}
+
+	rqs, err := c.client.CoreV1().ResourceQuotas(q.Name()).List(metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+
+	for i := range rqs.Items {
+		rq := &rqs.Items[i]
+		if rq.Spec.Hard == nil {
+			rq.Spec.Hard = v1.ResourceList{}
+		}
+		for name, qty := range q.Queue().Status.Deserved.Resources {
+			rq.Spec.Hard[v1.ResourceName("limits."+string(name))] = qty
+		}
+		if _, err := c.client.CoreV1().ResourceQuotas(q.Name()).Update(rq); err != nil {
+			return fmt.Errorf("fail to update resource quota %s/%s, %#v", rq.Namespace, rq.Name, err)
+		}
+	}
+	q.Queue().Status.Allocated = q.Queue().Status.Deserved.DeepCopy()
+
+	return nil
+}
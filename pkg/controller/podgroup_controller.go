@@ -0,0 +1,119 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"fmt"
+	"time"
+
+	apiv1 "github.com/kubernetes-incubator/kube-arbitrator/pkg/apis/v1"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/client"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/schedulercache"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	restclient "k8s.io/client-go/rest"
+)
+
+// podGroupLabel is set by whoever submits a gang of pods (e.g. a job
+// controller) to declare which PodGroup a pod belongs to. The
+// PodGroupController mirrors it onto GroupNameAnnotation so the rest of
+// the arbitrator only ever has to read one, stable key.
+const podGroupLabel = "scheduling.k8s.io/group-name"
+
+// PodGroupController keeps the schedulercache's view of PodGroups in
+// sync with the API server, and annotates member pods with their
+// group's name.
+type PodGroupController struct {
+	client kubernetes.Interface
+	crd    *restclient.RESTClient
+	cache  *schedulercache.Cache
+}
+
+// NewPodGroupController builds a controller that reconciles PodGroup
+// CRDs into cache.
+func NewPodGroupController(config *restclient.Config, cache *schedulercache.Cache) (*PodGroupController, error) {
+	crdClient, _, err := client.NewClient(config)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PodGroupController{
+		client: kubernetes.NewForConfigOrDie(config),
+		crd:    crdClient,
+		cache:  cache,
+	}, nil
+}
+
+// Run starts the reconciliation loop; it never returns.
+func (pc *PodGroupController) Run() {
+	for {
+		if err := pc.sync(); err != nil {
+			fmt.Printf("fail to sync pod groups, %#v\n", err)
+		}
+		time.Sleep(syncPeriod)
+	}
+}
+
+func (pc *PodGroupController) sync() error {
+	var groups apiv1.PodGroupList
+	err := pc.crd.Get().Resource(apiv1.PodGroupPlural).Do().Into(&groups)
+	if err != nil {
+		return err
+	}
+
+	for i := range groups.Items {
+		pg := &groups.Items[i]
+		pc.cache.UpdatePodGroup(pg)
+
+		if err := pc.labelGroupPods(pg); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// labelGroupPods annotates every pod carrying the podGroupLabel for pg
+// with GroupNameAnnotation, so the scheduler's view of group membership
+// doesn't depend on a label a caller could change after admission.
+func (pc *PodGroupController) labelGroupPods(pg *apiv1.PodGroup) error {
+	pods, err := pc.client.CoreV1().Pods(pg.Namespace).List(metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("%s=%s", podGroupLabel, pg.Name),
+	})
+	if err != nil {
+		return err
+	}
+
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if pod.Annotations[apiv1.GroupNameAnnotation] == pg.Name {
+			continue
+		}
+
+		if pod.Annotations == nil {
+			pod.Annotations = map[string]string{}
+		}
+		pod.Annotations[apiv1.GroupNameAnnotation] = pg.Name
+
+		if _, err := pc.client.CoreV1().Pods(pod.Namespace).Update(pod); err != nil {
+			return fmt.Errorf("fail to annotate pod %s/%s with group %s, %#v", pod.Namespace, pod.Name, pg.Name, err)
+		}
+	}
+
+	return nil
+}
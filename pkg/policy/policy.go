@@ -0,0 +1,48 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policy
+
+import (
+	apiv1 "github.com/kubernetes-incubator/kube-arbitrator/pkg/apis/v1"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/schedulercache"
+)
+
+// Interface is implemented by every arbitration policy (e.g.
+// "proportion"). Allocate recomputes each queue's QueueStatus in place
+// given the cluster's total allocatable resources.
+type Interface interface {
+	// Name returns the registered name of the policy.
+	Name() string
+
+	// Allocate recalculates Used/Guaranteed/Deserved for every queue in
+	// queues, given the cluster's total allocatable resources.
+	Allocate(queues []*schedulercache.QueueInfo, total apiv1.ResourceList) error
+}
+
+var policies = map[string]Interface{}
+
+// Register makes a policy available by name to New. Policies register
+// themselves from an init() function.
+func Register(p Interface) {
+	policies[p.Name()] = p
+}
+
+// New looks up a previously registered policy by name. It returns nil
+// if no policy was registered under that name.
+func New(name string) Interface {
+	return policies[name]
+}
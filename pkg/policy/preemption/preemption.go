@@ -0,0 +1,224 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package preemption reclaims resources from queues that are borrowing
+// above their deserved share so another queue can grow into its Min.
+package preemption
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	apiv1 "github.com/kubernetes-incubator/kube-arbitrator/pkg/apis/v1"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/schedulercache"
+
+	"k8s.io/api/core/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	restclient "k8s.io/client-go/rest"
+)
+
+// noPendingPriorityFloor is used when the policy has no concrete pod to
+// attribute the reclamation to (e.g. a brand new, still-empty queue
+// coming online): with no floor to respect, any victim with room to
+// give is fair game.
+const noPendingPriorityFloor = math.MaxInt32
+
+// Interface reclaims resources from over-quota queues.
+type Interface interface {
+	// Preempt walks queues and evicts just enough pods from queues that
+	// are using more than their Deserved share to bring them back down,
+	// never touching a queue whose Used is already within its
+	// Guaranteed (Min) floor.
+	Preempt(queues []*schedulercache.QueueInfo) error
+}
+
+type preemptor struct {
+	config *restclient.Config
+	client kubernetes.Interface
+}
+
+// New returns the default preemptor for the given kubeconfig.
+func New(config *restclient.Config) Interface {
+	return &preemptor{
+		config: config,
+		client: kubernetes.NewForConfigOrDie(config),
+	}
+}
+
+func (p *preemptor) Preempt(queues []*schedulercache.QueueInfo) error {
+	for _, q := range queues {
+		if q.Queue().Spec.PreemptionPolicy == apiv1.Never {
+			continue
+		}
+		// A queue using no more than its guaranteed Min is never a
+		// reclamation target, regardless of how the rest of the
+		// cluster is shifting.
+		if q.UsedUnderGuaranteed() {
+			continue
+		}
+		// Not currently borrowing above its deserved share: nothing to
+		// reclaim from this queue.
+		if q.UsedUnderDeserved() {
+			continue
+		}
+
+		if err := p.evictDownToDeserved(q, donorPendingPriority(q, queues)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// donorPendingPriority is the floor eviction from donor must respect:
+// the highest priority among pods still Pending in another queue that
+// is both under its own deserved share and short on a resource donor is
+// currently holding above its deserved share. A queue waiting on
+// memory, say, is not a legitimate claimant on cpu donor is hoarding,
+// so it must not license evicting donor's pods; only a queue actually
+// competing for the dimension(s) donor is over on can raise the floor.
+// If no such pod exists, it returns noPendingPriorityFloor, so every pod
+// in donor is a candidate victim.
+func donorPendingPriority(donor *schedulercache.QueueInfo, queues []*schedulercache.QueueInfo) int32 {
+	borrowed := overDeservedResources(donor)
+	if len(borrowed) == 0 {
+		return noPendingPriorityFloor
+	}
+
+	floor := int32(noPendingPriorityFloor)
+	found := false
+
+	for _, q := range queues {
+		if q == donor || !q.UsedUnderDeserved() || !shortOnAny(q, borrowed) {
+			continue
+		}
+		for _, pod := range q.PendingPods() {
+			if pri := podPriority(pod); !found || pri > floor {
+				floor = pri
+				found = true
+			}
+		}
+	}
+
+	if !found {
+		return noPendingPriorityFloor
+	}
+	return floor
+}
+
+// overDeservedResources returns the resource names where donor's Used
+// exceeds its Deserved share, i.e. the dimensions eviction would
+// actually be reclaiming.
+func overDeservedResources(donor *schedulercache.QueueInfo) []apiv1.ResourceName {
+	used := donor.Queue().Status.Used.Resources
+	deserved := donor.Queue().Status.Deserved.Resources
+
+	names := make(map[apiv1.ResourceName]bool, len(used)+len(deserved))
+	for name := range used {
+		names[name] = true
+	}
+	for name := range deserved {
+		names[name] = true
+	}
+
+	var over []apiv1.ResourceName
+	for name := range names {
+		// a missing key defaults to the Quantity zero value.
+		if used[name].Cmp(deserved[name]) > 0 {
+			over = append(over, name)
+		}
+	}
+	return over
+}
+
+// shortOnAny reports whether q's Used falls below its Deserved on at
+// least one of names.
+func shortOnAny(q *schedulercache.QueueInfo, names []apiv1.ResourceName) bool {
+	used := q.Queue().Status.Used.Resources
+	deserved := q.Queue().Status.Deserved.Resources
+
+	for _, name := range names {
+		if used[name].Cmp(deserved[name]) < 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// evictDownToDeserved evicts the lowest-priority pods out of the
+// queue's namespace until Used is at or below Deserved, stopping as soon
+// as Used is within Guaranteed. Each eviction subtracts the pod's
+// requests from Used. It never evicts a pod whose priority
+// is already >= pendingPriority, and skips (rather than fails on) a
+// pod an active PodDisruptionBudget is protecting.
+func (p *preemptor) evictDownToDeserved(q *schedulercache.QueueInfo, pendingPriority int32) error {
+	for _, pod := range selectVictims(q.Pods, pendingPriority) {
+		if q.UsedUnderDeserved() || q.UsedUnderGuaranteed() {
+			break
+		}
+
+		eviction := &policyv1beta1.Eviction{
+			ObjectMeta: metav1.ObjectMeta{Name: pod.Name, Namespace: pod.Namespace},
+		}
+		err := p.client.PolicyV1beta1().Evictions(pod.Namespace).Evict(eviction)
+		if apierrors.IsTooManyRequests(err) {
+			// a PodDisruptionBudget is protecting this pod right now;
+			// leave it running and move on to the next victim.
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("fail to evict pod %s/%s, %#v", pod.Namespace, pod.Name, err)
+		}
+		delete(q.Pods, pod.Name)
+
+		used := q.Queue().Status.Used.Resources
+		for _, c := range pod.Spec.Containers {
+			for name, qty := range c.Resources.Requests {
+				u := used[apiv1.ResourceName(name)]
+				u.Sub(qty)
+				used[apiv1.ResourceName(name)] = u
+			}
+		}
+	}
+	return nil
+}
+
+// selectVictims returns pods lower priority than pendingPriority,
+// sorted ascending so the least important pod is evicted first. If no
+// pod qualifies, it returns an empty slice: every candidate is at least as
+// important as whoever is waiting, so nothing should be evicted.
+func selectVictims(pods map[string]*v1.Pod, pendingPriority int32) []*v1.Pod {
+	victims := make([]*v1.Pod, 0, len(pods))
+	for _, pod := range pods {
+		if podPriority(pod) < pendingPriority {
+			victims = append(victims, pod)
+		}
+	}
+	sort.Slice(victims, func(i, j int) bool {
+		return podPriority(victims[i]) < podPriority(victims[j])
+	})
+	return victims
+}
+
+func podPriority(pod *v1.Pod) int32 {
+	if pod.Spec.Priority == nil {
+		return 0
+	}
+	return *pod.Spec.Priority
+}
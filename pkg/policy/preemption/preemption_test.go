@@ -0,0 +1,92 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package preemption
+
+import (
+	"testing"
+
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func int32Ptr(v int32) *int32 {
+	return &v
+}
+
+func podWithPriority(name string, priority int32) *v1.Pod {
+	return &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec:       v1.PodSpec{Priority: int32Ptr(priority)},
+	}
+}
+
+// TestSelectVictimsSkipsCriticalPods verifies that, in a queue mixing
+// system-cluster-critical and best-effort pods, only the best-effort
+// ones are ever chosen as victims.
+func TestSelectVictimsSkipsCriticalPods(t *testing.T) {
+	const systemClusterCritical = int32(2000000000)
+
+	pods := map[string]*v1.Pod{
+		"critical-1": podWithPriority("critical-1", systemClusterCritical),
+		"critical-2": podWithPriority("critical-2", systemClusterCritical),
+		"best-1":     podWithPriority("best-1", 0),
+		"best-2":     podWithPriority("best-2", 0),
+	}
+
+	victims := selectVictims(pods, systemClusterCritical)
+
+	if len(victims) != 2 {
+		t.Fatalf("expected 2 victims, got %d: %#v", len(victims), victims)
+	}
+	for _, v := range victims {
+		if v.Name != "best-1" && v.Name != "best-2" {
+			t.Fatalf("critical pod %s was selected as a victim", v.Name)
+		}
+	}
+}
+
+// TestSelectVictimsOrdersByPriorityAscending verifies victims are
+// returned lowest priority first.
+func TestSelectVictimsOrdersByPriorityAscending(t *testing.T) {
+	pods := map[string]*v1.Pod{
+		"mid":  podWithPriority("mid", 5),
+		"low":  podWithPriority("low", 0),
+		"high": podWithPriority("high", 10),
+	}
+
+	victims := selectVictims(pods, 100)
+	if len(victims) != 3 {
+		t.Fatalf("expected 3 victims, got %d", len(victims))
+	}
+	if victims[0].Name != "low" || victims[1].Name != "mid" || victims[2].Name != "high" {
+		t.Fatalf("victims not sorted ascending by priority: %#v", victims)
+	}
+}
+
+// TestSelectVictimsNoneQualify verifies that when every candidate is at
+// least as important as the pending pod, no victim is returned.
+func TestSelectVictimsNoneQualify(t *testing.T) {
+	pods := map[string]*v1.Pod{
+		"a": podWithPriority("a", 10),
+		"b": podWithPriority("b", 20),
+	}
+
+	victims := selectVictims(pods, 10)
+	if len(victims) != 0 {
+		t.Fatalf("expected no victims, got %#v", victims)
+	}
+}
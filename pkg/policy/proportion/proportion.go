@@ -0,0 +1,353 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package proportion implements a weighted dominant-resource-fairness
+// (DRF) policy: every queue is first guaranteed its Min, then leftover
+// cluster capacity is split across every resource dimension - cpu,
+// memory, nvidia.com/gpu, or any other - so that no queue's dominant
+// share (the largest fraction of any single resource's total capacity
+// it holds) grows ahead of another's, bounded by each queue's Weight,
+// remaining room under Max and remaining demand.
+package proportion
+
+import (
+	"math"
+
+	apiv1 "github.com/kubernetes-incubator/kube-arbitrator/pkg/apis/v1"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/policy"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/schedulercache"
+
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// PolicyName is the name this policy registers itself under.
+const PolicyName = "proportion"
+
+type proportionPolicy struct{}
+
+func init() {
+	policy.Register(New())
+}
+
+// New returns the proportion policy.
+func New() policy.Interface {
+	return &proportionPolicy{}
+}
+
+func (pp *proportionPolicy) Name() string {
+	return PolicyName
+}
+
+// Allocate recomputes Used, Guaranteed and Deserved for every queue.
+// Used is the sum of requests of the queue's pods that have not
+// terminated. Guaranteed and Deserved are computed as:
+//  1. Guaranteed = min(Spec.Min, demand), so a queue never lays claim
+//     to more than it can use even from its own floor.
+//  2. leftover = total - sum(Guaranteed)
+//  3. leftover is split across queues by dominant resource fairness
+//     (see allocateDRF), bounded by each queue's remaining room under
+//     Max and its remaining demand.
+func (pp *proportionPolicy) Allocate(queues []*schedulercache.QueueInfo, total apiv1.ResourceList) error {
+	resNames := unionResourceNames(total, nil)
+	for _, q := range queues {
+		resNames = addResourceNames(resNames, q.Queue().Spec.Min)
+		resNames = addResourceNames(resNames, q.Queue().Spec.Max)
+	}
+
+	demands := make(map[string]apiv1.ResourceList, len(queues))
+	guaranteed := make(map[string]apiv1.ResourceList, len(queues))
+	leftover := total.DeepCopy()
+
+	for _, q := range queues {
+		d := q.Demand()
+		demands[q.Name()] = d
+		resNames = addResourceNames(resNames, d)
+
+		g := make(apiv1.ResourceList, len(resNames))
+		for _, name := range resNames {
+			min := quantityOf(q.Queue().Spec.Min, name)
+			dv := quantityOf(d, name)
+			g[name] = minQuantity(min, dv)
+		}
+		guaranteed[q.Name()] = g
+
+		for _, name := range resNames {
+			lv := quantityOf(leftover, name)
+			lv.Sub(g[name])
+			if lv.Sign() < 0 {
+				lv = resource.MustParse("0")
+			}
+			leftover[name] = lv
+		}
+	}
+
+	shares := allocateDRF(queues, resNames, leftover, guaranteed, demands)
+
+	for _, q := range queues {
+		status := q.Queue().Status
+		status.Guaranteed = apiv1.ResourceInfo{Resources: guaranteed[q.Name()]}
+
+		used := apiv1.ResourceList{}
+		for _, pod := range q.Pods {
+			if pod.Status.Phase == v1.PodSucceeded || pod.Status.Phase == v1.PodFailed {
+				continue
+			}
+			for _, c := range pod.Spec.Containers {
+				for name, qty := range c.Resources.Requests {
+					u := used[apiv1.ResourceName(name)]
+					u.Add(qty)
+					used[apiv1.ResourceName(name)] = u
+				}
+			}
+		}
+		status.Used = apiv1.ResourceInfo{Resources: used}
+
+		deserved := guaranteed[q.Name()].DeepCopy()
+		for name, share := range shares[q.Name()] {
+			dv := deserved[name]
+			dv.Add(share)
+			deserved[name] = dv
+		}
+		status.Deserved = apiv1.ResourceInfo{Resources: deserved}
+		q.Queue().Status = status
+	}
+
+	return nil
+}
+
+// allocateDRF splits leftover across queues by weighted dominant
+// resource fairness. Each queue grows its allocation along its own
+// remaining-demand vector (so the mix of resources it receives always
+// matches the mix it asked for) so that its dominant share rises at a
+// rate proportional to its Weight, until every queue is either fully satisfied or blocked by
+// a saturated resource or its own Max. This is the standard DRF
+// progressive-filling algorithm: whichever single resource a queue
+// leans on hardest, no queue is allowed to out-grow another's share of
+// its own dominant resource.
+func allocateDRF(queues []*schedulercache.QueueInfo, resNames []apiv1.ResourceName, leftover apiv1.ResourceList, guaranteed, demands map[string]apiv1.ResourceList) map[string]apiv1.ResourceList {
+	const epsilon = 1e-6
+
+	remaining := make(map[apiv1.ResourceName]float64, len(resNames))
+	for _, name := range resNames {
+		remaining[name] = float64(quantityOf(leftover, name).MilliValue())
+	}
+
+	type queueState struct {
+		queue  *schedulercache.QueueInfo
+		weight float64
+		want   map[apiv1.ResourceName]float64
+		got    map[apiv1.ResourceName]float64
+	}
+
+	states := make([]*queueState, 0, len(queues))
+	for _, q := range queues {
+		weight := float64(q.Queue().Spec.Weight)
+		if weight <= 0 {
+			continue
+		}
+
+		want := make(map[apiv1.ResourceName]float64, len(resNames))
+		for _, name := range resNames {
+			var w float64
+			if hasDemand(demands[q.Name()], name) {
+				got := float64(guaranteed[q.Name()][name].MilliValue())
+				w = float64(quantityOf(demands[q.Name()], name).MilliValue()) - got
+			} else {
+				// unknown demand: let the queue compete for the whole
+				// of this dimension rather than clamping it to zero
+				// before any pod has been scheduled.
+				w = remaining[name]
+			}
+			if w < 0 {
+				w = 0
+			}
+			if !isZeroResource(q.Queue().Spec.Max, name) {
+				room := float64(quantityOf(q.Queue().Spec.Max, name).MilliValue()) - float64(guaranteed[q.Name()][name].MilliValue())
+				if room < 0 {
+					room = 0
+				}
+				if w > room {
+					w = room
+				}
+			}
+			want[name] = w
+		}
+
+		states = append(states, &queueState{
+			queue:  q,
+			weight: weight,
+			want:   want,
+			got:    make(map[apiv1.ResourceName]float64, len(resNames)),
+		})
+	}
+
+	for {
+		active := make([]*queueState, 0, len(states))
+		for _, s := range states {
+			for _, name := range resNames {
+				if s.want[name] > epsilon {
+					active = append(active, s)
+					break
+				}
+			}
+		}
+		if len(active) == 0 {
+			break
+		}
+
+		// dominant is each active queue's largest remaining want as a
+		// fraction of that resource's leftover capacity; growing along
+		// want/dominant raises the queue's dominant share by weight per
+		// unit of t.
+		dominant := make(map[*queueState]float64, len(active))
+		for _, s := range active {
+			for _, name := range resNames {
+				if c := float64(quantityOf(leftover, name).MilliValue()); c > 0 && s.want[name]/c > dominant[s] {
+					dominant[s] = s.want[name] / c
+				}
+			}
+		}
+
+		// minT is how far every active queue can grow, in lockstep,
+		// along its own want vector before the first bottleneck hits:
+		// either a resource saturates, or a queue's own demand (and
+		// hence its want) is fully met.
+		minT := math.Inf(1)
+		for _, name := range resNames {
+			demandOnResource := 0.0
+			for _, s := range active {
+				demandOnResource += s.weight * s.want[name] / dominant[s]
+			}
+			if demandOnResource <= 0 {
+				continue
+			}
+			if t := remaining[name] / demandOnResource; t < minT {
+				minT = t
+			}
+		}
+		for _, s := range active {
+			if t := dominant[s] / s.weight; t < minT {
+				minT = t
+			}
+		}
+		if math.IsInf(minT, 1) || minT <= 0 {
+			break
+		}
+
+		for _, s := range active {
+			for _, name := range resNames {
+				if s.want[name] <= 0 {
+					continue
+				}
+				delta := minT * s.weight * s.want[name] / dominant[s]
+				s.got[name] += delta
+				s.want[name] -= delta
+				remaining[name] -= delta
+				if remaining[name] < epsilon {
+					remaining[name] = 0
+				}
+			}
+		}
+		for _, name := range resNames {
+			if remaining[name] <= 0 {
+				for _, s := range states {
+					s.want[name] = 0
+				}
+			}
+		}
+	}
+
+	shares := make(map[string]apiv1.ResourceList, len(states))
+	for _, s := range states {
+		share := make(apiv1.ResourceList, len(resNames))
+		for _, name := range resNames {
+			share[name] = *resource.NewMilliQuantity(int64(math.Round(s.got[name])), resource.DecimalSI)
+		}
+		shares[s.queue.Name()] = share
+	}
+	return shares
+}
+
+func unionResourceNames(a, b apiv1.ResourceList) []apiv1.ResourceName {
+	seen := map[apiv1.ResourceName]bool{}
+	names := []apiv1.ResourceName{}
+	for name := range a {
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	for name := range b {
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// addResourceNames appends every name in list not already present in
+// names, preserving names' existing order.
+func addResourceNames(names []apiv1.ResourceName, list apiv1.ResourceList) []apiv1.ResourceName {
+	seen := make(map[apiv1.ResourceName]bool, len(names))
+	for _, name := range names {
+		seen[name] = true
+	}
+	for name := range list {
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+func quantityOf(list apiv1.ResourceList, name apiv1.ResourceName) resource.Quantity {
+	if list == nil {
+		return resource.MustParse("0")
+	}
+	return list[name].DeepCopy()
+}
+
+func isZeroResource(list apiv1.ResourceList, name apiv1.ResourceName) bool {
+	if list == nil {
+		return true
+	}
+	v, ok := list[name]
+	if !ok {
+		return true
+	}
+	return v.IsZero()
+}
+
+// hasDemand reports whether we have observed any pods for this queue
+// yet. A namespace with no pods yet has an unknown, not zero, demand -
+// it should not be clamped down before any pod has been scheduled.
+func hasDemand(list apiv1.ResourceList, name apiv1.ResourceName) bool {
+	if list == nil {
+		return false
+	}
+	v, ok := list[name]
+	return ok && !v.IsZero()
+}
+
+func minQuantity(a, b resource.Quantity) resource.Quantity {
+	if a.Cmp(b) <= 0 {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,221 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schedulercache
+
+import (
+	"fmt"
+	"sync"
+
+	apiv1 "github.com/kubernetes-incubator/kube-arbitrator/pkg/apis/v1"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/client"
+
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/client-go/kubernetes"
+	restclient "k8s.io/client-go/rest"
+	k8scache "k8s.io/client-go/tools/cache"
+)
+
+// Cache watches Queue CRDs and pods and keeps an in-memory, namespace
+// keyed view of QueueInfo for the policies to read.
+type Cache struct {
+	sync.Mutex
+
+	config *restclient.Config
+	client kubernetes.Interface
+
+	queues map[string]*QueueInfo
+}
+
+// New builds a Cache for the given kubeconfig. The returned Cache does
+// not start watching until Run is called.
+func New(config *restclient.Config) *Cache {
+	return &Cache{
+		config: config,
+		client: kubernetes.NewForConfigOrDie(config),
+		queues: make(map[string]*QueueInfo),
+	}
+}
+
+// Run starts the SharedInformers backing the cache - one watching Queue
+// CRDs, one watching pods across every namespace - and blocks until
+// stopCh is closed.
+func (c *Cache) Run(stopCh <-chan struct{}) {
+	crdClient, _, err := client.NewClient(c.config)
+	if err != nil {
+		fmt.Printf("fail to build arbitrator crd client, %#v\n", err)
+		return
+	}
+
+	queueLW := k8scache.NewListWatchFromClient(crdClient, apiv1.QueuePlural, v1.NamespaceAll, fields.Everything())
+	_, queueInformer := k8scache.NewInformer(queueLW, &apiv1.Queue{}, 0, k8scache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			c.Update(obj.(*apiv1.Queue))
+		},
+		UpdateFunc: func(old, new interface{}) {
+			c.Update(new.(*apiv1.Queue))
+		},
+		DeleteFunc: func(obj interface{}) {
+			if queue, ok := obj.(*apiv1.Queue); ok {
+				c.Delete(queue)
+				return
+			}
+			if tomb, ok := obj.(k8scache.DeletedFinalStateUnknown); ok {
+				if queue, ok := tomb.Obj.(*apiv1.Queue); ok {
+					c.Delete(queue)
+				}
+			}
+		},
+	})
+
+	podLW := k8scache.NewListWatchFromClient(c.client.CoreV1().RESTClient(), "pods", v1.NamespaceAll, fields.Everything())
+	_, podInformer := k8scache.NewInformer(podLW, &v1.Pod{}, 0, k8scache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			c.UpdatePod(obj.(*v1.Pod))
+		},
+		UpdateFunc: func(old, new interface{}) {
+			c.UpdatePod(new.(*v1.Pod))
+		},
+		DeleteFunc: func(obj interface{}) {
+			if pod, ok := obj.(*v1.Pod); ok {
+				c.DeletePod(pod)
+				return
+			}
+			if tomb, ok := obj.(k8scache.DeletedFinalStateUnknown); ok {
+				if pod, ok := tomb.Obj.(*v1.Pod); ok {
+					c.DeletePod(pod)
+				}
+			}
+		},
+	})
+
+	go queueInformer.Run(stopCh)
+	go podInformer.Run(stopCh)
+	<-stopCh
+}
+
+// Snapshot returns a point-in-time copy of every known QueueInfo, safe
+// for a policy to read without holding the cache lock.
+func (c *Cache) Snapshot() []*QueueInfo {
+	c.Lock()
+	defer c.Unlock()
+
+	snapshot := make([]*QueueInfo, 0, len(c.queues))
+	for _, q := range c.queues {
+		snapshot = append(snapshot, q.Clone())
+	}
+	return snapshot
+}
+
+// Update replaces the cached entry for queue's namespace, creating it if
+// this is the first time the namespace has been seen.
+func (c *Cache) Update(queue *apiv1.Queue) {
+	c.Lock()
+	defer c.Unlock()
+
+	qi, ok := c.queues[queue.Namespace]
+	if !ok {
+		qi = &QueueInfo{
+			name:      queue.Namespace,
+			Pods:      make(map[string]*v1.Pod),
+			PodGroups: make(map[string]*PodGroupInfo),
+		}
+		c.queues[queue.Namespace] = qi
+	}
+	qi.queue = queue
+}
+
+// Delete removes the cached entry for queue's namespace entirely, since
+// without a Queue CRD there is nothing left to arbitrate for it.
+func (c *Cache) Delete(queue *apiv1.Queue) {
+	c.Lock()
+	defer c.Unlock()
+
+	delete(c.queues, queue.Namespace)
+}
+
+// UpdatePodGroup records pg against the queue identified by its
+// namespace, creating the queue entry if it hasn't been seen yet.
+func (c *Cache) UpdatePodGroup(pg *apiv1.PodGroup) {
+	c.Lock()
+	defer c.Unlock()
+
+	qi, ok := c.queues[pg.Namespace]
+	if !ok {
+		qi = &QueueInfo{
+			name:      pg.Namespace,
+			Pods:      make(map[string]*v1.Pod),
+			PodGroups: make(map[string]*PodGroupInfo),
+		}
+		c.queues[pg.Namespace] = qi
+	}
+
+	group, ok := qi.PodGroups[pg.Name]
+	if !ok {
+		group = &PodGroupInfo{
+			Name:      pg.Name,
+			Namespace: pg.Namespace,
+			Pods:      make(map[string]*v1.Pod),
+		}
+		qi.PodGroups[pg.Name] = group
+	}
+	group.MinMember = pg.Spec.MinMember
+	group.Min = pg.Spec.MinResources
+}
+
+// UpdatePod records pod against the queue identified by its namespace,
+// and against the PodGroup named by its GroupNameAnnotation, if any.
+func (c *Cache) UpdatePod(pod *v1.Pod) {
+	c.Lock()
+	defer c.Unlock()
+
+	qi, ok := c.queues[pod.Namespace]
+	if !ok {
+		qi = &QueueInfo{
+			name:      pod.Namespace,
+			Pods:      make(map[string]*v1.Pod),
+			PodGroups: make(map[string]*PodGroupInfo),
+		}
+		c.queues[pod.Namespace] = qi
+	}
+	qi.Pods[pod.Name] = pod
+
+	if groupName := pod.Annotations[apiv1.GroupNameAnnotation]; groupName != "" {
+		if g, ok := qi.PodGroups[groupName]; ok {
+			g.Pods[pod.Name] = pod
+		}
+	}
+}
+
+// DeletePod removes pod from its queue and, if it belonged to one, its
+// PodGroup.
+func (c *Cache) DeletePod(pod *v1.Pod) {
+	c.Lock()
+	defer c.Unlock()
+
+	qi, ok := c.queues[pod.Namespace]
+	if !ok {
+		return
+	}
+	delete(qi.Pods, pod.Name)
+
+	if groupName := pod.Annotations[apiv1.GroupNameAnnotation]; groupName != "" {
+		if g, ok := qi.PodGroups[groupName]; ok {
+			delete(g.Pods, pod.Name)
+		}
+	}
+}
@@ -27,21 +27,54 @@ type QueueInfo struct {
 	name  string
 	queue *apiv1.Queue
 	Pods  map[string]*v1.Pod
+
+	// PodGroups indexes the gang-scheduled groups charged against this
+	// queue, keyed by PodGroup name.
+	PodGroups map[string]*PodGroupInfo
 }
 
-// true  - all resources(cpu/memory) in res1 < res2
-// false - not above case
-func compareResources(res1 map[apiv1.ResourceName]resource.Quantity, res2 map[apiv1.ResourceName]resource.Quantity) bool {
-	cpu1 := res1["cpu"].DeepCopy()
-	cpu2 := res2["cpu"].DeepCopy()
-	memory1 := res1["memory"].DeepCopy()
-	memory2 := res2["memory"].DeepCopy()
+// PodGroupInfo tracks a PodGroup and the member pods observed so far.
+type PodGroupInfo struct {
+	Name      string
+	Namespace string
+	MinMember int32
+	Min       apiv1.ResourceList
+	Pods      map[string]*v1.Pod
+}
 
-	if cpu1.Cmp(cpu2) <= 0 && memory1.Cmp(memory2) <= 0 {
-		return true
+// Ready reports whether this group already has at least MinMember pods
+// running.
+func (g *PodGroupInfo) Ready() bool {
+	running := int32(0)
+	for _, pod := range g.Pods {
+		if pod.Status.Phase == v1.PodRunning {
+			running++
+		}
+	}
+	return running >= g.MinMember
+}
+
+// compareResources reports whether every dimension of res1 is <= its
+// counterpart in res2, treating a resource missing from either map as
+// zero. It iterates the union of both maps' keys so arbitrary/extended
+// resources (nvidia.com/gpu, hugepages-2Mi, ...) are compared exactly
+// like cpu and memory, not just the two built-in ones.
+func compareResources(res1 map[apiv1.ResourceName]resource.Quantity, res2 map[apiv1.ResourceName]resource.Quantity) bool {
+	names := make(map[apiv1.ResourceName]bool, len(res1)+len(res2))
+	for name := range res1 {
+		names[name] = true
+	}
+	for name := range res2 {
+		names[name] = true
 	}
 
-	return false
+	for name := range names {
+		// a missing key defaults to the Quantity zero value.
+		if res1[name].Cmp(res2[name]) > 0 {
+			return false
+		}
+	}
+	return true
 }
 
 func (r *QueueInfo) Name() string {
@@ -60,11 +93,109 @@ func (r *QueueInfo) UsedUnderDeserved() bool {
 	return compareResources(r.queue.Status.Used.Resources, r.queue.Status.Deserved.Resources)
 }
 
+// UsedUnderGuaranteed reports whether the queue is using no more than
+// its guaranteed Min; such a queue is never a preemption target.
+func (r *QueueInfo) UsedUnderGuaranteed() bool {
+	return compareResources(r.queue.Status.Used.Resources, r.queue.Status.Guaranteed.Resources)
+}
+
+// Demand sums the resource requests of every pod currently tracked
+// under this queue's namespace. A pod that is a member of a PodGroup we
+// know about is not counted individually; instead its group's
+// MinResources is counted once, since the group is admitted or rejected
+// as a whole rather than pod by pod.
+func (r *QueueInfo) Demand() apiv1.ResourceList {
+	demand := apiv1.ResourceList{}
+	countedGroups := map[string]bool{}
+
+	for _, pod := range r.Pods {
+		groupName := pod.Annotations[apiv1.GroupNameAnnotation]
+		if g, ok := r.PodGroups[groupName]; ok && groupName != "" {
+			if countedGroups[groupName] {
+				continue
+			}
+			countedGroups[groupName] = true
+			for name, qty := range g.Min {
+				total := demand[name]
+				total.Add(qty)
+				demand[name] = total
+			}
+			continue
+		}
+
+		for _, c := range pod.Spec.Containers {
+			for name, qty := range c.Resources.Requests {
+				resName := apiv1.ResourceName(name)
+				total := demand[resName]
+				total.Add(qty)
+				demand[resName] = total
+			}
+		}
+	}
+	return demand
+}
+
+// PendingPods returns the pods tracked under this queue that have not
+// yet been scheduled.
+func (r *QueueInfo) PendingPods() []*v1.Pod {
+	pending := make([]*v1.Pod, 0, len(r.Pods))
+	for _, pod := range r.Pods {
+		if pod.Status.Phase == v1.PodPending {
+			pending = append(pending, pod)
+		}
+	}
+	return pending
+}
+
+// AllGroupsReady reports whether every PodGroup charged against this
+// queue can either already field MinMember pods, or still has enough
+// room under the queue's deserved allocation to do so. It is false as
+// soon as one group is stuck partially running, signalling that its
+// queue's ResourceQuota must not be grown until the group can fit
+// whole.
+func (r *QueueInfo) AllGroupsReady() bool {
+	remaining := subtractResources(r.queue.Status.Deserved.Resources, r.queue.Status.Used.Resources)
+	for _, g := range r.PodGroups {
+		if g.Ready() {
+			continue
+		}
+		if !compareResources(g.Min, remaining) {
+			return false
+		}
+	}
+	return true
+}
+
+func subtractResources(res1 map[apiv1.ResourceName]resource.Quantity, res2 map[apiv1.ResourceName]resource.Quantity) apiv1.ResourceList {
+	out := apiv1.ResourceList{}
+	for name, qty := range res1 {
+		out[name] = qty.DeepCopy()
+	}
+	for name, qty := range res2 {
+		v := out[name]
+		v.Sub(qty)
+		out[name] = v
+	}
+	return out
+}
+
 func (r *QueueInfo) Clone() *QueueInfo {
 	clone := &QueueInfo{
-		name:  r.name,
-		queue: r.queue.DeepCopy(),
-		Pods:  r.Pods,
+		name:      r.name,
+		queue:     r.queue.DeepCopy(),
+		Pods:      make(map[string]*v1.Pod, len(r.Pods)),
+		PodGroups: make(map[string]*PodGroupInfo, len(r.PodGroups)),
+	}
+	for name, pod := range r.Pods {
+		clone.Pods[name] = pod
+	}
+	for name, g := range r.PodGroups {
+		gc := *g
+		gc.Pods = make(map[string]*v1.Pod, len(g.Pods))
+		for podName, pod := range g.Pods {
+			gc.Pods[podName] = pod
+		}
+		clone.PodGroups[name] = &gc
 	}
 	return clone
 }
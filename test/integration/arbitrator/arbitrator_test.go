@@ -496,3 +496,443 @@ func TestArbitrator(t *testing.T) {
 		t.Fatalf("after preemption, pods size is not 6 for ns02, %#v", pods02.Items)
 	}
 }
+
+// prepareCRDWithMin prepares two queues with a guaranteed Min:
+// "queue04" under "ns04" has Min=3 cpu and weight=1
+// "queue05" under "ns05" has Min=0 cpu and weight=1, so it is free to
+// borrow "queue04"'s idle capacity until "queue04" needs it back.
+func prepareCRDWithMin(config *restclient.Config) error {
+	extensionscs, err := apiextensionsclient.NewForConfig(config)
+	if err != nil {
+		return fmt.Errorf("fail to create crd config, %#v", err)
+	}
+
+	_, err = client.CreateQueueCRD(extensionscs)
+	if err != nil && !apierrors.IsAlreadyExists(err) {
+		return fmt.Errorf("fail to create crd, %#v", err)
+	}
+
+	crdClient, _, err := client.NewClient(config)
+	if err != nil {
+		return fmt.Errorf("fail to create crd client, %#v", err)
+	}
+
+	cases := []struct {
+		name   string
+		ns     string
+		weight int
+		min    resource.Quantity
+	}{
+		{
+			name:   "queue04",
+			ns:     "ns04",
+			weight: 1,
+			min:    resource.MustParse("3"),
+		},
+		{
+			name:   "queue05",
+			ns:     "ns05",
+			weight: 1,
+			min:    resource.MustParse("0"),
+		},
+	}
+
+	for _, c := range cases {
+		crd := &apiv1.Queue{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      c.name,
+				Namespace: c.ns,
+			},
+			Spec: apiv1.QueueSpec{
+				Weight: c.weight,
+				Min:    apiv1.ResourceList{"cpu": c.min},
+			},
+		}
+
+		var result apiv1.Queue
+		err = crdClient.Post().
+			Resource(apiv1.QueuePlural).
+			Namespace(crd.Namespace).
+			Body(crd).
+			Do().Into(&result)
+		if err != nil {
+			return fmt.Errorf("fail to create crd %s, %#v", crd.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// prepareCRDQueue06 creates "queue06" under "ns06" with weight=1 and no
+// Min; it becomes active after "queue04" and "queue05" have split the
+// cluster between them, shrinking "queue04"'s share, which must not
+// drop below its Min.
+func prepareCRDQueue06(config *restclient.Config) error {
+	crdClient, _, err := client.NewClient(config)
+	if err != nil {
+		return fmt.Errorf("fail to create crd client, %#v", err)
+	}
+
+	crd := &apiv1.Queue{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "queue06",
+			Namespace: "ns06",
+		},
+		Spec: apiv1.QueueSpec{
+			Weight: 1,
+		},
+	}
+
+	var result apiv1.Queue
+	err = crdClient.Post().
+		Resource(apiv1.QueuePlural).
+		Namespace(crd.Namespace).
+		Body(crd).
+		Do().Into(&result)
+	if err != nil {
+		return fmt.Errorf("fail to create crd %s, %#v", crd.Name, err)
+	}
+
+	return nil
+}
+
+// TestArbitratorMinMax checks that a queue with a Min keeps at least its
+// Min once a third, previously idle queue becomes active and every
+// queue's share shrinks.
+func TestArbitratorMinMax(t *testing.T) {
+	config, tearDown := framework.StartTestServerOrDie(t)
+	defer tearDown()
+
+	cs := clientset.NewForConfigOrDie(config)
+	defer cs.CoreV1().Nodes().DeleteCollection(nil, metav1.ListOptions{})
+
+	for _, ns := range []string{"ns04", "ns05", "ns06"} {
+		_, err := cs.CoreV1().Namespaces().Create(&v1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{Name: ns},
+		})
+		if err != nil {
+			t.Fatalf("fail to create namespace %s, %#v", ns, err)
+		}
+	}
+
+	if err := prepareNode(cs); err != nil {
+		t.Fatalf("fail to prepare node, %#v", err)
+	}
+
+	for _, c := range []struct{ name, ns string }{
+		{"rq04", "ns04"}, {"rq05", "ns05"}, {"rq06", "ns06"},
+	} {
+		_, err := cs.CoreV1().ResourceQuotas(c.ns).Create(&v1.ResourceQuota{
+			ObjectMeta: metav1.ObjectMeta{Name: c.name, Namespace: c.ns},
+			Spec: v1.ResourceQuotaSpec{
+				Hard: v1.ResourceList{v1.ResourcePods: resource.MustParse("1000")},
+			},
+		})
+		if err != nil {
+			t.Fatalf("fail to create quota %s, %#v", c.name, err)
+		}
+	}
+
+	if err := prepareCRDWithMin(config); err != nil {
+		t.Fatalf("fail to prepare CRD, %#v", err)
+	}
+
+	neverStop := make(chan struct{})
+	defer close(neverStop)
+	cache := schedulercache.New(config)
+	go cache.Run(neverStop)
+	c := controller.NewQueueController(config, cache, policy.New(proportion.PolicyName), preemption.New(config))
+	go c.Run()
+
+	// sleep to wait scheduler finish: no namespace has pods, so every
+	// queue's demand is unknown and the 15 cpus are split by weight.
+	time.Sleep(10 * time.Second)
+
+	// bring a third queue online; the cpus are now split three ways, and
+	// "queue04" must still get at least its Min of 3 cpus.
+	if err := prepareCRDQueue06(config); err != nil {
+		t.Fatalf("fail to prepare CRD for queue06, %#v", err)
+	}
+
+	time.Sleep(10 * time.Second)
+
+	rq04, _ := cs.CoreV1().ResourceQuotas("ns04").Get("rq04", metav1.GetOptions{})
+	cpu04 := rq04.Spec.Hard["limits.cpu"]
+	if v, _ := (&cpu04).AsInt64(); v < int64(3) {
+		t.Fatalf("after reclamation, cpu for rq04 dropped below its Min of 3, %#v", rq04)
+	}
+}
+
+// prepareCRDQueue07 creates "queue07" under "ns07", with a Max of 3
+// cpu, and "pg07", a PodGroup in the same namespace asking for
+// MinMember=5 pods worth of 5 cpu total - more than the queue can ever
+// give it.
+func prepareCRDQueue07(config *restclient.Config) error {
+	extensionscs, err := apiextensionsclient.NewForConfig(config)
+	if err != nil {
+		return fmt.Errorf("fail to create crd config, %#v", err)
+	}
+
+	_, err = client.CreatePodGroupCRD(extensionscs)
+	if err != nil && !apierrors.IsAlreadyExists(err) {
+		return fmt.Errorf("fail to create podgroup crd, %#v", err)
+	}
+
+	crdClient, _, err := client.NewClient(config)
+	if err != nil {
+		return fmt.Errorf("fail to create crd client, %#v", err)
+	}
+
+	queue := &apiv1.Queue{
+		ObjectMeta: metav1.ObjectMeta{Name: "queue07", Namespace: "ns07"},
+		Spec: apiv1.QueueSpec{
+			Weight: 1,
+			Max:    apiv1.ResourceList{"cpu": resource.MustParse("3")},
+		},
+	}
+	var queueResult apiv1.Queue
+	err = crdClient.Post().
+		Resource(apiv1.QueuePlural).
+		Namespace(queue.Namespace).
+		Body(queue).
+		Do().Into(&queueResult)
+	if err != nil {
+		return fmt.Errorf("fail to create crd %s, %#v", queue.Name, err)
+	}
+
+	group := &apiv1.PodGroup{
+		ObjectMeta: metav1.ObjectMeta{Name: "pg07", Namespace: "ns07"},
+		Spec: apiv1.PodGroupSpec{
+			MinMember:    5,
+			Queue:        "queue07",
+			MinResources: apiv1.ResourceList{"cpu": resource.MustParse("5")},
+		},
+	}
+	var groupResult apiv1.PodGroup
+	err = crdClient.Post().
+		Resource(apiv1.PodGroupPlural).
+		Namespace(group.Namespace).
+		Body(group).
+		Do().Into(&groupResult)
+	if err != nil {
+		return fmt.Errorf("fail to create podgroup %s, %#v", group.Name, err)
+	}
+
+	return nil
+}
+
+// prepareGangPods creates 5 pods under ns07, each requesting 1 cpu and
+// labeled as members of "pg07".
+func prepareGangPods(cs *clientset.Clientset) error {
+	container := v1.Container{
+		Name:  "worker",
+		Image: "busybox",
+		Resources: v1.ResourceRequirements{
+			Limits:   v1.ResourceList{"cpu": resource.MustParse("1")},
+			Requests: v1.ResourceList{"cpu": resource.MustParse("1")},
+		},
+	}
+
+	for i := 1; i <= 5; i++ {
+		pod := &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      fmt.Sprintf("ns07-pod%02d", i),
+				Namespace: "ns07",
+				Labels:    map[string]string{"scheduling.k8s.io/group-name": "pg07"},
+			},
+			Spec: v1.PodSpec{Containers: []v1.Container{container}},
+		}
+		if _, err := cs.CoreV1().Pods(pod.Namespace).Create(pod); err != nil {
+			return fmt.Errorf("fail to create pod %s, %#v", pod.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// TestGangScheduling verifies that a PodGroup with MinMember=5 either
+// runs all 5 members or none of them, even though only 3 cpus are ever
+// free for its queue.
+func TestGangScheduling(t *testing.T) {
+	config, tearDown := framework.StartTestServerOrDie(t)
+	defer tearDown()
+
+	cs := clientset.NewForConfigOrDie(config)
+	defer cs.CoreV1().Nodes().DeleteCollection(nil, metav1.ListOptions{})
+
+	_, err := cs.CoreV1().Namespaces().Create(&v1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{Name: "ns07"},
+	})
+	if err != nil {
+		t.Fatalf("fail to create namespace ns07, %#v", err)
+	}
+
+	if err := prepareNode(cs); err != nil {
+		t.Fatalf("fail to prepare node, %#v", err)
+	}
+
+	_, err = cs.CoreV1().ResourceQuotas("ns07").Create(&v1.ResourceQuota{
+		ObjectMeta: metav1.ObjectMeta{Name: "rq07", Namespace: "ns07"},
+		Spec: v1.ResourceQuotaSpec{
+			Hard: v1.ResourceList{
+				v1.ResourcePods: resource.MustParse("1000"),
+				"limits.cpu":    resource.MustParse("3"),
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("fail to create quota rq07, %#v", err)
+	}
+
+	if err := prepareCRDQueue07(config); err != nil {
+		t.Fatalf("fail to prepare queue07/pg07, %#v", err)
+	}
+
+	neverStop := make(chan struct{})
+	defer close(neverStop)
+	cache := schedulercache.New(config)
+	go cache.Run(neverStop)
+	c := controller.NewQueueController(config, cache, policy.New(proportion.PolicyName), preemption.New(config))
+	go c.Run()
+
+	pgc, err := controller.NewPodGroupController(config, cache)
+	if err != nil {
+		t.Fatalf("fail to build pod group controller, %#v", err)
+	}
+	go pgc.Run()
+
+	if err := prepareGangPods(cs); err != nil {
+		t.Fatalf("fail to prepare gang pods, %#v", err)
+	}
+
+	// sleep to let the pod group controller annotate the 5 pods, and for
+	// the controller to notice pg07 can never reach MinMember=5 under
+	// queue07's Max of 3 cpus and evict its members.
+	time.Sleep(20 * time.Second)
+
+	pods, _ := cs.CoreV1().Pods("ns07").List(metav1.ListOptions{})
+	if len(pods.Items) != 0 && len(pods.Items) != 5 {
+		t.Fatalf("gang of pg07 is partially running, want 0 or 5, got %d, %#v", len(pods.Items), pods.Items)
+	}
+}
+
+// prepareGPUNode prepares one node "node02" which offers 4
+// "nvidia.com/gpu" devices in addition to cpu/memory, so a queue can be
+// arbitrated on an extended resource the same way it is on cpu.
+func prepareGPUNode(cs *clientset.Clientset) error {
+	node := &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node02", GenerateName: "node02"},
+		Spec:       v1.NodeSpec{ExternalID: "foo"},
+		Status: v1.NodeStatus{
+			Capacity: v1.ResourceList{
+				v1.ResourceCPU:    resource.MustParse("4"),
+				v1.ResourceMemory: resource.MustParse("4Gi"),
+				"nvidia.com/gpu":  resource.MustParse("4"),
+			},
+			Phase:      v1.NodeRunning,
+			Conditions: []v1.NodeCondition{{Type: v1.NodeReady, Status: v1.ConditionTrue}},
+		},
+	}
+
+	_, err := cs.CoreV1().Nodes().Create(node)
+	if err != nil {
+		return fmt.Errorf("fail to create node %s, %#v", node.Name, err)
+	}
+	return nil
+}
+
+// prepareCRDGPUQueues prepares "queue08" (weight=1) under "ns08" and
+// "queue09" (weight=3) under "ns09", neither setting Min/Max, so the
+// cluster's 4 GPUs should be divided 1:3 between them independently of
+// cpu.
+func prepareCRDGPUQueues(config *restclient.Config) error {
+	crdClient, _, err := client.NewClient(config)
+	if err != nil {
+		return fmt.Errorf("fail to create crd client, %#v", err)
+	}
+
+	cases := []struct {
+		name   string
+		ns     string
+		weight int
+	}{
+		{"queue08", "ns08", 1},
+		{"queue09", "ns09", 3},
+	}
+
+	for _, c := range cases {
+		crd := &apiv1.Queue{
+			ObjectMeta: metav1.ObjectMeta{Name: c.name, Namespace: c.ns},
+			Spec:       apiv1.QueueSpec{Weight: c.weight},
+		}
+		var result apiv1.Queue
+		err = crdClient.Post().
+			Resource(apiv1.QueuePlural).
+			Namespace(crd.Namespace).
+			Body(crd).
+			Do().Into(&result)
+		if err != nil {
+			return fmt.Errorf("fail to create crd %s, %#v", crd.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// TestGPUArbitration verifies that an extended resource (GPU) is
+// divided by weight independently of cpu, proving compareResources and
+// the proportion policy no longer special-case cpu/memory.
+func TestGPUArbitration(t *testing.T) {
+	config, tearDown := framework.StartTestServerOrDie(t)
+	defer tearDown()
+
+	cs := clientset.NewForConfigOrDie(config)
+	defer cs.CoreV1().Nodes().DeleteCollection(nil, metav1.ListOptions{})
+
+	for _, ns := range []string{"ns08", "ns09"} {
+		_, err := cs.CoreV1().Namespaces().Create(&v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: ns}})
+		if err != nil {
+			t.Fatalf("fail to create namespace %s, %#v", ns, err)
+		}
+	}
+
+	if err := prepareGPUNode(cs); err != nil {
+		t.Fatalf("fail to prepare GPU node, %#v", err)
+	}
+
+	for _, c := range []struct{ name, ns string }{{"rq08", "ns08"}, {"rq09", "ns09"}} {
+		_, err := cs.CoreV1().ResourceQuotas(c.ns).Create(&v1.ResourceQuota{
+			ObjectMeta: metav1.ObjectMeta{Name: c.name, Namespace: c.ns},
+			Spec: v1.ResourceQuotaSpec{
+				Hard: v1.ResourceList{v1.ResourcePods: resource.MustParse("1000")},
+			},
+		})
+		if err != nil {
+			t.Fatalf("fail to create quota %s, %#v", c.name, err)
+		}
+	}
+
+	if err := prepareCRDGPUQueues(config); err != nil {
+		t.Fatalf("fail to prepare GPU queues, %#v", err)
+	}
+
+	neverStop := make(chan struct{})
+	defer close(neverStop)
+	cache := schedulercache.New(config)
+	go cache.Run(neverStop)
+	c := controller.NewQueueController(config, cache, policy.New(proportion.PolicyName), preemption.New(config))
+	go c.Run()
+
+	time.Sleep(10 * time.Second)
+
+	rq08, _ := cs.CoreV1().ResourceQuotas("ns08").Get("rq08", metav1.GetOptions{})
+	gpu08 := rq08.Spec.Hard["limits.nvidia.com/gpu"]
+	if v, _ := (&gpu08).AsInt64(); v != int64(1) {
+		t.Fatalf("after scheduler, gpu is not 1 for rq08, %#v", rq08)
+	}
+	rq09, _ := cs.CoreV1().ResourceQuotas("ns09").Get("rq09", metav1.GetOptions{})
+	gpu09 := rq09.Spec.Hard["limits.nvidia.com/gpu"]
+	if v, _ := (&gpu09).AsInt64(); v != int64(3) {
+		t.Fatalf("after scheduler, gpu is not 3 for rq09, %#v", rq09)
+	}
+}